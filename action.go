@@ -0,0 +1,49 @@
+package pgrest
+
+import "fmt"
+
+// Action identifies the kind of operation a RestQuery performs. Values are
+// flag-style bits so All can be expressed as the bitwise OR of every single
+// action, letting callers test membership with Action&Get != 0 and the like.
+type Action int
+
+const (
+	// None is the zero value of Action, matching no action.
+	None Action = 0
+	// Get fetches a single resource by key.
+	Get Action = 1 << (iota - 1)
+	// Post inserts a new resource.
+	Post
+	// Put replaces a resource identified by key.
+	Put
+	// Patch partially updates a resource identified by key, touching only
+	// its non-zero fields.
+	Patch
+	// Delete removes a resource identified by key.
+	Delete
+)
+
+// All is every single action OR'd together, used by Repository.List to mark
+// a RestQuery as a bulk fetch rather than a single-resource Get.
+const All = Get | Post | Put | Patch | Delete
+
+func (a Action) String() string {
+	switch a {
+	case None:
+		return "None"
+	case Get:
+		return "Get"
+	case Post:
+		return "Post"
+	case Put:
+		return "Put"
+	case Patch:
+		return "Patch"
+	case Delete:
+		return "Delete"
+	case All:
+		return "All"
+	default:
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+}
@@ -0,0 +1,141 @@
+// Package audit provides Apache-style access logging for pgrest REST
+// actions: a middleware records every RestQuery as a structured Event and
+// hands it to one or more Sinks.
+package audit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aptogeo/pgrest"
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/go-pg/pg/v9"
+	"github.com/nsf/jsondiff"
+)
+
+// Event describes one completed REST action.
+type Event struct {
+	Action   pgrest.Action
+	Resource string
+	Key      string
+	Filters  []pgrest.Filter
+	Status   int
+	Latency  time.Duration
+	User     string
+	Header   http.Header
+	// Diff holds a rendered before/after JSON diff, populated only when
+	// Options.BodyDiff is set and Action is Patch or Put.
+	Diff string
+}
+
+// Sink receives audit Events. Implementations must be safe for concurrent use.
+type Sink interface {
+	Write(ctx context.Context, e Event) error
+}
+
+// Options configures Middleware.
+type Options struct {
+	// User extracts the caller's identity from the request context. Defaults
+	// to always returning "".
+	User func(ctx context.Context) string
+	// BodyDiff captures a before/after JSON diff for Patch and Put actions.
+	// Requires Fetch to be set; it is ignored otherwise.
+	BodyDiff bool
+	// Fetch returns the current JSON representation of the row identified by
+	// resource and key. Middleware calls it once before next.ServeHTTP and
+	// once after to obtain the before/after snapshots that BodyDiff diffs;
+	// q.Body itself is the caller's request payload, not the row's stored
+	// state, so it cannot be used for this.
+	Fetch func(ctx context.Context, resource, key string) ([]byte, error)
+	// DB, when set, makes Middleware wrap a mutating action (Post, Put, Patch
+	// or Delete) and the audit write in a single transaction, so the two
+	// commit or roll back together. Middleware begins the transaction and
+	// threads it through the request context via transactional.ContextWithDb,
+	// so next must call pgrest.Server.Execute with that context (as it does
+	// naturally via r.Context()) for the mutating query to join it rather
+	// than run on its own connection. Required for NewTableSink to be
+	// atomic; without DB set, the table sink inserts in its own transaction
+	// after next.ServeHTTP has already returned.
+	DB *pg.DB
+}
+
+// isMutating reports whether a completes by writing to the resource, i.e.
+// whether it is worth paying for a shared transaction with the audit insert.
+func isMutating(a pgrest.Action) bool {
+	switch a {
+	case pgrest.Post, pgrest.Put, pgrest.Patch, pgrest.Delete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Middleware wraps next, recording an Event to sink for every RestQuery it
+// handles. It recovers the RestQuery being served from the request context
+// via pgrest.QueryFromContext before calling next, so a handler upstream of
+// Middleware must have stored one with pgrest.ContextWithQuery; a query that
+// next itself stores in its own context is not seen.
+func Middleware(next http.Handler, sink Sink, opts Options) http.Handler {
+	if opts.User == nil {
+		opts.User = func(ctx context.Context) string { return "" }
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		var before []byte
+		q, hasQuery := pgrest.QueryFromContext(r.Context())
+		wantDiff := opts.BodyDiff && opts.Fetch != nil && hasQuery && (q.Action == pgrest.Patch || q.Action == pgrest.Put)
+		if wantDiff {
+			before, _ = opts.Fetch(r.Context(), q.Resource, q.Key)
+		}
+
+		serveAndRecord := func(ctx context.Context) error {
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if !hasQuery {
+				return nil
+			}
+			event := Event{
+				Action:   q.Action,
+				Resource: q.Resource,
+				Key:      q.Key,
+				Filters:  q.Filters,
+				Status:   rec.status,
+				Latency:  time.Since(start),
+				User:     opts.User(ctx),
+				Header:   r.Header,
+			}
+			if wantDiff {
+				after, _ := opts.Fetch(ctx, q.Resource, q.Key)
+				event.Diff = diffJSON(before, after)
+			}
+			return sink.Write(ctx, event)
+		}
+
+		if opts.DB != nil && hasQuery && isMutating(q.Action) {
+			ctx := transactional.ContextWithDb(r.Context(), opts.DB)
+			transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+				return serveAndRecord(ctx)
+			})
+			return
+		}
+		serveAndRecord(r.Context())
+	})
+}
+
+func diffJSON(before, after []byte) string {
+	opts := jsondiff.DefaultConsoleOptions()
+	_, diff := jsondiff.Compare(before, after, &opts)
+	return diff
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
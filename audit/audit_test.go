@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aptogeo/pgrest"
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubSink struct {
+	events []Event
+}
+
+func (s *stubSink) Write(ctx context.Context, e Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+type failingSink struct {
+	err error
+}
+
+func (s *failingSink) Write(ctx context.Context, e Event) error {
+	return s.err
+}
+
+type auditAtomicRow struct {
+	ID int64
+}
+
+func TestMiddlewareBodyDiff(t *testing.T) {
+	rows := map[string]string{"1": `{"text":"before"}`}
+	sink := &stubSink{}
+	opts := Options{
+		BodyDiff: true,
+		Fetch: func(ctx context.Context, resource, key string) ([]byte, error) {
+			return []byte(rows[key]), nil
+		},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rows["1"] = `{"text":"after"}`
+	})
+	handler := Middleware(next, sink, opts)
+
+	q := &pgrest.RestQuery{Action: pgrest.Patch, Resource: "todos", Key: "1", Body: `{"text":"after"}`}
+	req := httptest.NewRequest(http.MethodPatch, "/todos/1", nil)
+	req = req.WithContext(pgrest.ContextWithQuery(req.Context(), q))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 1, len(sink.events))
+	assert.NotEmpty(t, sink.events[0].Diff)
+}
+
+func TestMiddlewareAtomicRollsBackMutationOnSinkFailure(t *testing.T) {
+	db := pg.Connect(&pg.Options{
+		User:               "postgres",
+		Password:           "postgres",
+		IdleCheckFrequency: 100 * time.Millisecond,
+	})
+	assert.Nil(t, db.CreateTable((*auditAtomicRow)(nil), &orm.CreateTableOptions{Temp: true}))
+
+	opts := Options{DB: db}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transactional.Execute(r.Context(), func(ctx context.Context, tx *pg.Tx) error {
+			_, err := tx.ModelContext(ctx, &auditAtomicRow{ID: 1}).Insert()
+			return err
+		})
+	})
+	handler := Middleware(next, &failingSink{err: errors.New("sink boom")}, opts)
+
+	q := &pgrest.RestQuery{Action: pgrest.Post, Resource: "audit_atomic_rows"}
+	req := httptest.NewRequest(http.MethodPost, "/audit_atomic_rows", nil)
+	req = req.WithContext(pgrest.ContextWithQuery(req.Context(), q))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	count, err := db.Model((*auditAtomicRow)(nil)).Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// NewJSONSink returns a Sink that writes each Event as a JSON object to w,
+// one per line.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{enc: json.NewEncoder(w)}
+}
+
+type jsonSink struct {
+	enc *json.Encoder
+}
+
+func (s *jsonSink) Write(ctx context.Context, e Event) error {
+	return s.enc.Encode(e)
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aptogeo/pgrest"
+)
+
+// NewStdoutSink returns a Sink that writes one Apache-style log line per
+// Event to os.Stdout, rendered according to format. Recognized tokens:
+//
+//	%A action     %R resource   %K key        %F filters
+//	%s status     %T latency    %u user       %{header}i request header value
+func NewStdoutSink(format string) Sink {
+	return &writerSink{w: os.Stdout, format: format}
+}
+
+type writerSink struct {
+	w      io.Writer
+	format string
+}
+
+func (s *writerSink) Write(ctx context.Context, e Event) error {
+	_, err := fmt.Fprintln(s.w, render(s.format, e))
+	return err
+}
+
+func render(format string, e Event) string {
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			sb.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'A':
+			sb.WriteString(fmt.Sprintf("%v", e.Action))
+		case 'R':
+			sb.WriteString(e.Resource)
+		case 'K':
+			sb.WriteString(e.Key)
+		case 'F':
+			sb.WriteString(pgrest.FiltersString(e.Filters))
+		case 's':
+			sb.WriteString(strconv.Itoa(e.Status))
+		case 'T':
+			sb.WriteString(e.Latency.String())
+		case 'u':
+			sb.WriteString(e.User)
+		case '{':
+			end := strings.IndexByte(format[i:], '}')
+			if end == -1 || i+end+1 >= len(format) || format[i+end+1] != 'i' {
+				sb.WriteByte('%')
+				sb.WriteByte(format[i])
+				continue
+			}
+			header := format[i+1 : i+end]
+			sb.WriteString(e.Header.Get(header))
+			i += end + 1
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(format[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aptogeo/pgrest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "abc123")
+	e := Event{
+		Action:   pgrest.Get,
+		Resource: "todos",
+		Key:      "1",
+		Status:   200,
+		Latency:  150 * time.Millisecond,
+		User:     "alice",
+		Header:   header,
+	}
+	got := render("%A %R[%K] status=%s time=%T user=%u id=%{X-Request-Id}i", e)
+	assert.Equal(t, "Get todos[1] status=200 time=150ms user=alice id=abc123", got)
+}
+
+func TestRenderUnknownToken(t *testing.T) {
+	assert.Equal(t, "%Z", render("%Z", Event{}))
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aptogeo/pgrest"
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/go-pg/pg/v9"
+)
+
+// auditRow is the row inserted into the audit table by a TableSink.
+type auditRow struct {
+	Action    string
+	Resource  string
+	Key       string
+	Filters   string
+	Status    int
+	Latency   time.Duration
+	User      string
+	Diff      string
+	CreatedAt time.Time
+}
+
+// NewTableSink returns a Sink that inserts an audit row into tableName, via
+// transactional.Execute so it joins whatever transaction is already active
+// in ctx. When Middleware is configured with Options.DB, that is the same
+// transaction as the mutating query the event describes, threaded through
+// the request context: the mutation and the audit row then commit or roll
+// back together. Without Options.DB set, Write runs in its own top-level
+// transaction, no longer coupled to the mutating query.
+func NewTableSink(db *pg.DB, tableName string) Sink {
+	return &tableSink{db: db, tableName: tableName}
+}
+
+type tableSink struct {
+	db        *pg.DB
+	tableName string
+}
+
+func (s *tableSink) Write(ctx context.Context, e Event) error {
+	ctx = transactional.ContextWithDb(ctx, s.db)
+	return transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+		row := &auditRow{
+			Action:    fmt.Sprintf("%v", e.Action),
+			Resource:  e.Resource,
+			Key:       e.Key,
+			Filters:   pgrest.FiltersString(e.Filters),
+			Status:    e.Status,
+			Latency:   e.Latency,
+			User:      e.User,
+			Diff:      e.Diff,
+			CreatedAt: time.Now(),
+		}
+		_, err := tx.Model(row).Table(s.tableName).Insert()
+		return err
+	})
+}
@@ -0,0 +1,31 @@
+package dialect
+
+// cockroach targets CockroachDB. It reuses postgres for everything the two
+// databases share and only overrides savepoint support, which CockroachDB
+// gained only in later versions.
+type cockroach struct {
+	postgres
+	nestedSavepoints bool
+}
+
+// Cockroach returns a Dialect for CockroachDB. Set nestedSavepoints to false
+// when targeting a CockroachDB version that predates nested transaction
+// support, so Savepoint propagation in package transactional runs directly in
+// the current transaction instead of producing an unsupported statement.
+func Cockroach(nestedSavepoints bool) Dialect {
+	return cockroach{nestedSavepoints: nestedSavepoints}
+}
+
+func (c cockroach) SavepointSyntax(name string) string {
+	if !c.nestedSavepoints {
+		return ""
+	}
+	return "SAVEPOINT " + name
+}
+
+// SerialPrimaryKey returns the column type to use in place of SERIAL for a
+// primary key. CockroachDB recommends unique_rowid() over a sequence so
+// inserts distribute evenly across ranges instead of hot-spotting.
+func (cockroach) SerialPrimaryKey() string {
+	return "INT DEFAULT unique_rowid()"
+}
@@ -0,0 +1,27 @@
+// Package dialect abstracts the SQL differences between the backends pgrest
+// can target, so the query-building code in pgrest itself stays
+// database-agnostic. pgrest is built on go-pg, which only speaks Postgres'
+// wire protocol, so this covers Postgres itself and Postgres-wire-compatible
+// backends such as CockroachDB; a true MySQL/TiDB dialect would additionally
+// need a database/sql-based adapter in place of go-pg for Server,
+// transactional and Iterator, which does not exist here.
+package dialect
+
+// Dialect captures the SQL differences pgrest needs to account for across
+// backends.
+type Dialect interface {
+	// QuoteIdent quotes name as an identifier for this backend.
+	QuoteIdent(name string) string
+	// Placeholder returns the parameter placeholder for the i-th (1-based)
+	// bind argument.
+	Placeholder(i int) string
+	// LimitOffset renders the LIMIT/OFFSET clause for a page of results.
+	// Either bound may be zero to omit it; if both are zero it returns "".
+	LimitOffset(limit, offset uint64) string
+	// SupportsReturning reports whether the backend can return the affected
+	// row(s) from an INSERT/UPDATE/DELETE statement.
+	SupportsReturning() bool
+	// SavepointSyntax renders the SAVEPOINT statement for name, or returns
+	// an empty string if the backend does not support savepoints.
+	SavepointSyntax(name string) string
+}
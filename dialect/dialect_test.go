@@ -0,0 +1,32 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/aptogeo/pgrest/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectMatrix(t *testing.T) {
+	dialects := map[string]dialect.Dialect{
+		"postgres":  dialect.Postgres,
+		"cockroach": dialect.Cockroach(true),
+	}
+	for name, d := range dialects {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEmpty(t, d.QuoteIdent("todos"))
+			assert.NotEmpty(t, d.Placeholder(1))
+			assert.NotEmpty(t, d.LimitOffset(10, 20))
+		})
+	}
+	assert.True(t, dialect.Postgres.SupportsReturning())
+	assert.Equal(t, "SAVEPOINT sp1", dialect.Cockroach(true).SavepointSyntax("sp1"))
+	assert.Equal(t, "", dialect.Cockroach(false).SavepointSyntax("sp1"))
+}
+
+func TestLimitOffset(t *testing.T) {
+	assert.Equal(t, "LIMIT 10 OFFSET 20", dialect.Postgres.LimitOffset(10, 20))
+	assert.Equal(t, "LIMIT 10", dialect.Postgres.LimitOffset(10, 0))
+	assert.Equal(t, "OFFSET 20", dialect.Postgres.LimitOffset(0, 20))
+	assert.Equal(t, "", dialect.Postgres.LimitOffset(0, 0))
+}
@@ -0,0 +1,40 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Postgres is the default Dialect, targeting PostgreSQL itself.
+var Postgres Dialect = postgres{}
+
+type postgres struct{}
+
+func (postgres) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgres) Placeholder(i int) string {
+	return fmt.Sprintf("$%d", i)
+}
+
+func (postgres) LimitOffset(limit, offset uint64) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf("LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf("OFFSET %d", offset)
+	default:
+		return ""
+	}
+}
+
+func (postgres) SupportsReturning() bool {
+	return true
+}
+
+func (postgres) SavepointSyntax(name string) string {
+	return "SAVEPOINT " + name
+}
@@ -0,0 +1,26 @@
+package pgrest
+
+import (
+	"context"
+
+	"github.com/aptogeo/pgrest/dialect"
+)
+
+type dialectContextKey struct{}
+
+// ContextWithDialect returns a context carrying d, so raw-SQL code paths such
+// as the streaming Iterator can quote identifiers correctly for backends
+// other than PostgreSQL without threading a Dialect through every function
+// signature.
+func ContextWithDialect(ctx context.Context, d dialect.Dialect) context.Context {
+	return context.WithValue(ctx, dialectContextKey{}, d)
+}
+
+// DialectFromContext returns the Dialect stored in ctx by ContextWithDialect,
+// or dialect.Postgres if none was stored.
+func DialectFromContext(ctx context.Context) dialect.Dialect {
+	if d, ok := ctx.Value(dialectContextKey{}).(dialect.Dialect); ok {
+		return d
+	}
+	return dialect.Postgres
+}
@@ -0,0 +1,375 @@
+package pgrest
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+)
+
+// Operator identifies a comparison or boolean operator usable in a Filter.
+type Operator string
+
+// Comparison operators accepted on a single column.
+const (
+	Eq      Operator = "eq"
+	Neq     Operator = "neq"
+	Lt      Operator = "lt"
+	Lte     Operator = "lte"
+	Gt      Operator = "gt"
+	Gte     Operator = "gte"
+	Like    Operator = "like"
+	ILike   Operator = "ilike"
+	In      Operator = "in"
+	Nin     Operator = "nin"
+	IsNull  Operator = "isnull"
+	Between Operator = "between"
+)
+
+// Boolean operators used to group nested Filters.
+const (
+	And Operator = "and"
+	Or  Operator = "or"
+	Not Operator = "not"
+)
+
+var comparisonOperators = map[Operator]bool{
+	Eq: true, Neq: true, Lt: true, Lte: true, Gt: true, Gte: true,
+	Like: true, ILike: true, In: true, Nin: true, IsNull: true, Between: true,
+}
+
+// Filter structure. A Filter is either a single column comparison (Name, Op,
+// Value) or, when Group is set, a boolean-grouped list of nested Filters.
+type Filter struct {
+	Name  string
+	Op    Operator
+	Value string
+	Group *FilterGroup
+}
+
+func (f *Filter) String() string {
+	if f.Group != nil {
+		return f.Group.String()
+	}
+	return fmt.Sprintf("%v=%v.%v", f.Name, f.Op, f.Value)
+}
+
+// groupItemString renders f the way it appears inside a FilterGroup, i.e.
+// "name.op.value" for a plain comparison or "op=(...)" for a nested group,
+// rather than the top-level "name=op.value", matching what parseFilterGroup
+// expects to read back.
+func (f *Filter) groupItemString() string {
+	if f.Group != nil {
+		return f.Group.String()
+	}
+	return fmt.Sprintf("%v.%v.%v", f.Name, f.Op, f.Value)
+}
+
+// FilterGroup structure groups Filters under a boolean Operator (And, Or or Not).
+type FilterGroup struct {
+	Op      Operator
+	Filters []Filter
+}
+
+func (g *FilterGroup) String() string {
+	parts := make([]string, 0, len(g.Filters))
+	for _, f := range g.Filters {
+		parts = append(parts, f.groupItemString())
+	}
+	return fmt.Sprintf("%v=(%v)", g.Op, strings.Join(parts, ","))
+}
+
+// ParseFilters parses a PostgREST-style query string (e.g.
+// "name=like.foo*&age=gte.18&or=(status.eq.new,status.eq.open)") into a slice
+// of Filter. Boolean groups nest freely, e.g.
+// "and=(status.eq.new,or=(a.eq.1,a.eq.2))".
+func ParseFilters(values url.Values) ([]Filter, error) {
+	filters := make([]Filter, 0, len(values))
+	for name, vs := range values {
+		for _, v := range vs {
+			f, err := parseFilter(name, v)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f)
+		}
+	}
+	return filters, nil
+}
+
+func parseFilter(name string, value string) (Filter, error) {
+	switch Operator(name) {
+	case And, Or, Not:
+		group, err := parseFilterGroup(Operator(name), value)
+		if err != nil {
+			return Filter{}, err
+		}
+		return Filter{Group: group}, nil
+	}
+	op, val, err := parseOpValue(value)
+	if err != nil {
+		return Filter{}, err
+	}
+	return Filter{Name: name, Op: op, Value: val}, nil
+}
+
+func parseOpValue(value string) (Operator, string, error) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("pgrest: invalid filter value %q, expected op.value", value)
+	}
+	op := Operator(parts[0])
+	if !comparisonOperators[op] {
+		return "", "", fmt.Errorf("pgrest: unknown filter operator %q", parts[0])
+	}
+	return op, parts[1], nil
+}
+
+func parseFilterGroup(op Operator, raw string) (*FilterGroup, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "(") || !strings.HasSuffix(raw, ")") {
+		return nil, fmt.Errorf("pgrest: invalid group %q, expected (...)", raw)
+	}
+	group := &FilterGroup{Op: op}
+	for _, item := range splitGroupItems(raw[1 : len(raw)-1]) {
+		f, err := parseGroupItem(item)
+		if err != nil {
+			return nil, err
+		}
+		group.Filters = append(group.Filters, f)
+	}
+	return group, nil
+}
+
+// parseGroupItem parses one member of a FilterGroup: either a nested boolean
+// group written as "op=(...)" (e.g. "or=(a.eq.1,a.eq.2)"), recursing via
+// parseFilterGroup, or a flat "name.op.value" comparison.
+func parseGroupItem(item string) (Filter, error) {
+	if name, rest, ok := strings.Cut(item, "="); ok {
+		if op := Operator(name); op == And || op == Or || op == Not {
+			group, err := parseFilterGroup(op, rest)
+			if err != nil {
+				return Filter{}, err
+			}
+			return Filter{Group: group}, nil
+		}
+	}
+	parts := strings.SplitN(item, ".", 3)
+	if len(parts) != 3 {
+		return Filter{}, fmt.Errorf("pgrest: invalid group filter %q, expected name.op.value or and/or/not=(...)", item)
+	}
+	itemOp := Operator(parts[1])
+	if !comparisonOperators[itemOp] {
+		return Filter{}, fmt.Errorf("pgrest: unknown filter operator %q", parts[1])
+	}
+	return Filter{Name: parts[0], Op: itemOp, Value: parts[2]}, nil
+}
+
+// splitGroupItems splits a comma separated "name.op.value" list, ignoring
+// commas nested inside parentheses.
+func splitGroupItems(raw string) []string {
+	var items []string
+	depth := 0
+	last := 0
+	for i, r := range raw {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, raw[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(items, raw[last:])
+}
+
+// FiltersString renders filters for logging, e.g. in RestQuery.String() and
+// audit event formatting. It is needed because Filter.String has a pointer
+// receiver: fmt's %v does not promote to it over a []Filter value, so
+// rendering filters with %v directly would print raw struct dumps instead of
+// the round-trip query-string syntax Filter.String produces.
+func FiltersString(filters []Filter) string {
+	parts := make([]string, len(filters))
+	for i := range filters {
+		parts[i] = filters[i].String()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// ApplyFilters translates filters into WHERE clauses on q, quoting column
+// identifiers and coercing values to the resource's column types via
+// reflection. It returns an error if a filter references an unknown column.
+func ApplyFilters(q *orm.Query, filters []Filter) error {
+	table := q.TableModel().Table()
+	for _, f := range filters {
+		if f.Group != nil {
+			if err := applyFilterGroup(q, table, f.Group); err != nil {
+				return err
+			}
+			continue
+		}
+		cond, args, err := condition(table, f)
+		if err != nil {
+			return err
+		}
+		q.Where(cond, args...)
+	}
+	return nil
+}
+
+func applyFilterGroup(q *orm.Query, table *orm.Table, g *FilterGroup) error {
+	clause, args, err := groupCondition(table, g)
+	if err != nil {
+		return err
+	}
+	q.Where(clause, args...)
+	return nil
+}
+
+// groupCondition renders g as a single parenthesized clause: members are
+// joined with OR for an Or group and AND for an And or Not group, and a Not
+// group additionally negates the whole thing.
+func groupCondition(table *orm.Table, g *FilterGroup) (string, []interface{}, error) {
+	joiner := " AND "
+	if g.Op == Or {
+		joiner = " OR "
+	}
+	var sb strings.Builder
+	var args []interface{}
+	for i, f := range g.Filters {
+		var cond string
+		var condArgs []interface{}
+		var err error
+		if f.Group != nil {
+			cond, condArgs, err = groupCondition(table, f.Group)
+		} else {
+			cond, condArgs, err = condition(table, f)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if i > 0 {
+			sb.WriteString(joiner)
+		}
+		sb.WriteString(cond)
+		args = append(args, condArgs...)
+	}
+	if g.Op == Not {
+		return fmt.Sprintf("NOT (%v)", sb.String()), args, nil
+	}
+	return fmt.Sprintf("(%v)", sb.String()), args, nil
+}
+
+func condition(table *orm.Table, f Filter) (string, []interface{}, error) {
+	field := table.FieldsMap[f.Name]
+	if field == nil {
+		return "", nil, fmt.Errorf("pgrest: unknown column %q", f.Name)
+	}
+	ident := pg.Ident(field.Column)
+	switch f.Op {
+	case Eq, Neq, Lt, Lte, Gt, Gte:
+		v, err := coerce(field.Type, f.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("? %v ?", sqlComparator(f.Op)), []interface{}{ident, v}, nil
+	case Like:
+		return "? LIKE ?", []interface{}{ident, likePattern(f.Value)}, nil
+	case ILike:
+		return "? ILIKE ?", []interface{}{ident, likePattern(f.Value)}, nil
+	case IsNull:
+		if f.Value == "true" {
+			return "? IS NULL", []interface{}{ident}, nil
+		}
+		return "? IS NOT NULL", []interface{}{ident}, nil
+	case In, Nin:
+		values := strings.Split(f.Value, ",")
+		coerced := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			cv, err := coerce(field.Type, v)
+			if err != nil {
+				return "", nil, err
+			}
+			coerced = append(coerced, cv)
+		}
+		if f.Op == Nin {
+			return "? NOT IN (?)", []interface{}{ident, pg.In(coerced)}, nil
+		}
+		return "? IN (?)", []interface{}{ident, pg.In(coerced)}, nil
+	case Between:
+		bounds := strings.SplitN(f.Value, ",", 2)
+		if len(bounds) != 2 {
+			return "", nil, fmt.Errorf("pgrest: between requires two comma separated values, got %q", f.Value)
+		}
+		lo, err := coerce(field.Type, bounds[0])
+		if err != nil {
+			return "", nil, err
+		}
+		hi, err := coerce(field.Type, bounds[1])
+		if err != nil {
+			return "", nil, err
+		}
+		return "? BETWEEN ? AND ?", []interface{}{ident, lo, hi}, nil
+	}
+	return "", nil, fmt.Errorf("pgrest: unsupported operator %q", f.Op)
+}
+
+// likePattern translates a PostgREST-style Like/ILike value such as "foo*"
+// into a SQL LIKE pattern, escaping any literal backslash, %, or _ in the
+// value (using Postgres' default LIKE escape character, backslash) before
+// turning * into the SQL wildcard %.
+func likePattern(raw string) string {
+	var sb strings.Builder
+	for _, r := range raw {
+		switch r {
+		case '\\', '%', '_':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '*':
+			sb.WriteByte('%')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func sqlComparator(op Operator) string {
+	switch op {
+	case Neq:
+		return "!="
+	case Lt:
+		return "<"
+	case Lte:
+		return "<="
+	case Gt:
+		return ">"
+	case Gte:
+		return ">="
+	default:
+		return "="
+	}
+}
+
+func coerce(t reflect.Type, raw string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	default:
+		return raw, nil
+	}
+}
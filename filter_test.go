@@ -0,0 +1,103 @@
+package pgrest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFiltersSimple(t *testing.T) {
+	values := url.Values{"age": []string{"gte.18"}}
+	filters, err := ParseFilters(values)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(filters))
+	assert.Equal(t, "age", filters[0].Name)
+	assert.Equal(t, Gte, filters[0].Op)
+	assert.Equal(t, "18", filters[0].Value)
+	assert.Equal(t, "age=gte.18", filters[0].String())
+}
+
+func TestParseFiltersInvalidOperator(t *testing.T) {
+	_, err := ParseFilters(url.Values{"age": []string{"foo.18"}})
+	assert.NotNil(t, err)
+}
+
+func TestParseFiltersGroup(t *testing.T) {
+	values := url.Values{"or": []string{"(status.eq.new,status.eq.open)"}}
+	filters, err := ParseFilters(values)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(filters))
+	group := filters[0].Group
+	assert.NotNil(t, group)
+	assert.Equal(t, Or, group.Op)
+	assert.Equal(t, 2, len(group.Filters))
+	assert.Equal(t, "or=(status.eq.new,status.eq.open)", filters[0].String())
+}
+
+func TestParseFiltersGroupRoundTrips(t *testing.T) {
+	original := url.Values{"or": []string{"(status.eq.new,status.eq.open)"}}
+	filters, err := ParseFilters(original)
+	assert.Nil(t, err)
+
+	values, err := url.ParseQuery(filters[0].String())
+	assert.Nil(t, err)
+	reparsed, err := ParseFilters(values)
+	assert.Nil(t, err)
+	assert.Equal(t, filters, reparsed)
+}
+
+func TestParseFiltersNotGroup(t *testing.T) {
+	filters, err := ParseFilters(url.Values{"not": []string{"(status.eq.archived)"}})
+	assert.Nil(t, err)
+	assert.Equal(t, Not, filters[0].Group.Op)
+	assert.Equal(t, "not=(status.eq.archived)", filters[0].String())
+}
+
+func TestParseFiltersNestedGroup(t *testing.T) {
+	values := url.Values{"and": []string{"(status.eq.new,or=(a.eq.1,a.eq.2))"}}
+	filters, err := ParseFilters(values)
+	assert.Nil(t, err)
+	group := filters[0].Group
+	assert.Equal(t, And, group.Op)
+	assert.Equal(t, 2, len(group.Filters))
+	assert.Equal(t, "status", group.Filters[0].Name)
+	nested := group.Filters[1].Group
+	assert.NotNil(t, nested)
+	assert.Equal(t, Or, nested.Op)
+	assert.Equal(t, 2, len(nested.Filters))
+	assert.Equal(t, "and=(status.eq.new,or=(a.eq.1,a.eq.2))", filters[0].String())
+}
+
+func TestParseFiltersNestedGroupRoundTrips(t *testing.T) {
+	original := url.Values{"and": []string{"(status.eq.new,or=(a.eq.1,a.eq.2))"}}
+	filters, err := ParseFilters(original)
+	assert.Nil(t, err)
+
+	values, err := url.ParseQuery(filters[0].String())
+	assert.Nil(t, err)
+	reparsed, err := ParseFilters(values)
+	assert.Nil(t, err)
+	assert.Equal(t, filters, reparsed)
+}
+
+func TestParseFiltersInvalidGroupFilter(t *testing.T) {
+	_, err := ParseFilters(url.Values{"or": []string{"(bogus)"}})
+	assert.NotNil(t, err)
+}
+
+func TestLikePatternTranslatesWildcard(t *testing.T) {
+	assert.Equal(t, "foo%", likePattern("foo*"))
+	assert.Equal(t, "%foo%", likePattern("*foo*"))
+}
+
+func TestLikePatternEscapesLiteralWildcardChars(t *testing.T) {
+	assert.Equal(t, `100\%`, likePattern("100%"))
+	assert.Equal(t, `foo\_bar`, likePattern("foo_bar"))
+	assert.Equal(t, `foo\\bar%`, likePattern(`foo\bar*`))
+}
+
+func TestFiltersStringRoundTripsEachFilter(t *testing.T) {
+	filters := []Filter{{Name: "age", Op: Gte, Value: "18"}, {Name: "name", Op: Eq, Value: "bob"}}
+	assert.Equal(t, "[age=gte.18 name=eq.bob]", FiltersString(filters))
+}
@@ -0,0 +1,42 @@
+package pgrest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// WriteJSON streams it to w as a JSON array using chunked transfer encoding,
+// emitting each row as soon as it is fetched instead of buffering the whole
+// result set. The caller is still responsible for calling it.Close().
+func WriteJSON(w http.ResponseWriter, it *Iterator) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	flusher, canFlush := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+	first := true
+	for {
+		var row map[string]interface{}
+		if !it.Next(&row) {
+			break
+		}
+		if !first {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+	}
+	if _, err := w.Write([]byte("]")); err != nil {
+		return err
+	}
+	return it.Err()
+}
@@ -0,0 +1,190 @@
+package pgrest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aptogeo/pgrest/dialect"
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/go-pg/pg/v9"
+)
+
+// quoteIdent quotes name for d and marks the result as already-safe SQL, so
+// go-pg inserts it verbatim instead of quoting it a second time as it would
+// for a bare string.
+func quoteIdent(d dialect.Dialect, name string) pg.Safe {
+	return pg.Safe(d.QuoteIdent(name))
+}
+
+// defaultBatchSize is used when RestQuery.BatchSize is left at zero.
+const defaultBatchSize = 500
+
+// Iterator streams the rows of a list-style RestQuery in batches instead of
+// buffering the entire result set in memory. Close must be called once the
+// caller is done with it, which releases the cursor and commits the
+// underlying transaction.
+type Iterator struct {
+	tx        *pg.Tx
+	dialect   dialect.Dialect
+	name      string
+	batchSize uint64
+	buf       reflect.Value
+	pos       int
+	done      bool
+	err       error
+}
+
+// Execute runs a list-style q and returns an Iterator over the matching
+// rows, backed by a server-side cursor. Set q.BatchSize to control how many
+// rows are fetched from the database per round trip; it defaults to 500.
+func Execute(ctx context.Context, q *RestQuery) (*Iterator, error) {
+	batchSize := q.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	tx, _, err := transactional.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d := DialectFromContext(ctx)
+	name := fmt.Sprintf("pgrest_cursor_%p", tx)
+	sqlText, args, err := buildSelect(d, q)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	args = append([]interface{}{quoteIdent(d, name)}, args...)
+	if _, err := tx.Exec("DECLARE ? CURSOR FOR "+sqlText, args...); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &Iterator{tx: tx, dialect: d, name: name, batchSize: batchSize}, nil
+}
+
+func buildSelect(d dialect.Dialect, q *RestQuery) (string, []interface{}, error) {
+	sqlText := "SELECT * FROM ?"
+	args := []interface{}{quoteIdent(d, q.Resource)}
+	for i, f := range q.Filters {
+		if i == 0 {
+			sqlText += " WHERE "
+		} else {
+			sqlText += " AND "
+		}
+		clause, clauseArgs, err := rawCondition(d, f)
+		if err != nil {
+			return "", nil, err
+		}
+		sqlText += clause
+		args = append(args, clauseArgs...)
+	}
+	for i, s := range q.Sorts {
+		if i == 0 {
+			sqlText += " ORDER BY "
+		} else {
+			sqlText += ", "
+		}
+		dir := "ASC"
+		if !s.Asc {
+			dir = "DESC"
+		}
+		sqlText += "? " + dir
+		args = append(args, quoteIdent(d, s.Name))
+	}
+	if clause := d.LimitOffset(q.Limit, q.Offset); clause != "" {
+		sqlText += " " + clause
+	}
+	return sqlText, args, nil
+}
+
+// rawCondition renders a Filter without the struct-based type coercion
+// ApplyFilters performs, since Execute works against a bare resource name
+// rather than a registered Go type. It returns an error for a grouped
+// filter, since Execute's raw-SQL builder has no reflection-backed table to
+// resolve column names against, unlike ApplyFilters' groupCondition.
+func rawCondition(d dialect.Dialect, f Filter) (string, []interface{}, error) {
+	if f.Group != nil {
+		return "", nil, fmt.Errorf("pgrest: filter groups are not supported by the streaming Iterator")
+	}
+	ident := quoteIdent(d, f.Name)
+	switch f.Op {
+	case Like:
+		return "? LIKE ?", []interface{}{ident, likePattern(f.Value)}, nil
+	case ILike:
+		return "? ILIKE ?", []interface{}{ident, likePattern(f.Value)}, nil
+	case IsNull:
+		if f.Value == "true" {
+			return "? IS NULL", []interface{}{ident}, nil
+		}
+		return "? IS NOT NULL", []interface{}{ident}, nil
+	case In, Nin:
+		values := strings.Split(f.Value, ",")
+		coerced := make([]interface{}, len(values))
+		for i, v := range values {
+			coerced[i] = v
+		}
+		if f.Op == Nin {
+			return "? NOT IN (?)", []interface{}{ident, pg.In(coerced)}, nil
+		}
+		return "? IN (?)", []interface{}{ident, pg.In(coerced)}, nil
+	case Between:
+		bounds := strings.SplitN(f.Value, ",", 2)
+		if len(bounds) != 2 {
+			return "", nil, fmt.Errorf("pgrest: between requires two comma separated values, got %q", f.Value)
+		}
+		return "? BETWEEN ? AND ?", []interface{}{ident, bounds[0], bounds[1]}, nil
+	case Eq, Neq, Lt, Lte, Gt, Gte:
+		return fmt.Sprintf("? %v ?", sqlComparator(f.Op)), []interface{}{ident, f.Value}, nil
+	}
+	return "", nil, fmt.Errorf("pgrest: unsupported operator %q", f.Op)
+}
+
+// Next decodes the next row into dst and reports whether a row was
+// available. Once it returns false, call Err to distinguish exhaustion from
+// a failure.
+func (it *Iterator) Next(dst interface{}) bool {
+	if it.done {
+		return false
+	}
+	if !it.buf.IsValid() || it.pos >= it.buf.Len() {
+		if !it.fetch(dst) {
+			return false
+		}
+	}
+	reflect.ValueOf(dst).Elem().Set(it.buf.Index(it.pos))
+	it.pos++
+	return true
+}
+
+func (it *Iterator) fetch(dst interface{}) bool {
+	elemType := reflect.TypeOf(dst).Elem()
+	slicePtr := reflect.New(reflect.SliceOf(elemType))
+	_, err := it.tx.Query(slicePtr.Interface(), "FETCH FORWARD ? FROM ?", it.batchSize, quoteIdent(it.dialect, it.name))
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	it.buf = slicePtr.Elem()
+	it.pos = 0
+	if it.buf.Len() == 0 {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Err returns the first error encountered while fetching rows, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close releases the cursor and commits the underlying transaction.
+func (it *Iterator) Close() error {
+	if _, err := it.tx.Exec("CLOSE ?", quoteIdent(it.dialect, it.name)); err != nil {
+		it.tx.Rollback()
+		return err
+	}
+	return it.tx.Commit()
+}
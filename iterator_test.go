@@ -0,0 +1,58 @@
+package pgrest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptogeo/pgrest"
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteIteratesInBatches(t *testing.T) {
+	srv := newTestServer(t)
+	todos := pgrest.RegisterResource[Todo](srv, "todos", pgrest.Options{})
+	for _, text := range []string{"a", "b", "c"} {
+		assert.Nil(t, todos.Insert(context.Background(), &Todo{Text: text}))
+	}
+
+	ctx := transactional.ContextWithDb(context.Background(), srv.DB())
+	it, err := pgrest.Execute(ctx, &pgrest.RestQuery{Resource: "todos", BatchSize: 2})
+	assert.Nil(t, err)
+	defer it.Close()
+
+	var count int
+	var todo Todo
+	for it.Next(&todo) {
+		count++
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 3, count)
+}
+
+func TestExecuteHonorsLimitAndOffset(t *testing.T) {
+	srv := newTestServer(t)
+	todos := pgrest.RegisterResource[Todo](srv, "todos", pgrest.Options{})
+	for _, text := range []string{"a", "b", "c"} {
+		assert.Nil(t, todos.Insert(context.Background(), &Todo{Text: text}))
+	}
+
+	ctx := transactional.ContextWithDb(context.Background(), srv.DB())
+	it, err := pgrest.Execute(ctx, &pgrest.RestQuery{
+		Resource: "todos",
+		Sorts:    []pgrest.Sort{{Name: "text", Asc: true}},
+		Limit:    1,
+		Offset:   1,
+	})
+	assert.Nil(t, err)
+	defer it.Close()
+
+	var count int
+	var todo Todo
+	for it.Next(&todo) {
+		count++
+		assert.Equal(t, "b", todo.Text)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 1, count)
+}
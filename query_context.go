@@ -0,0 +1,19 @@
+package pgrest
+
+import "context"
+
+type queryContextKey struct{}
+
+// ContextWithQuery returns a context carrying q, so downstream code (such as
+// audit middleware) can recover the RestQuery being served without
+// threading it through every function signature.
+func ContextWithQuery(ctx context.Context, q *RestQuery) context.Context {
+	return context.WithValue(ctx, queryContextKey{}, q)
+}
+
+// QueryFromContext returns the RestQuery stored in ctx by ContextWithQuery,
+// if any.
+func QueryFromContext(ctx context.Context) (*RestQuery, bool) {
+	q, ok := ctx.Value(queryContextKey{}).(*RestQuery)
+	return q, ok
+}
@@ -0,0 +1,135 @@
+package pgrest
+
+import "context"
+
+// Options configures a Resource registered with RegisterResource.
+type Options struct {
+	// PrimaryKey is the struct field holding the resource key. Defaults to "ID".
+	PrimaryKey string
+}
+
+// Repository is the strongly-typed façade produced by RegisterResource. It
+// wraps the server's untyped RestQuery dispatch so callers work with T
+// directly instead of interface{}.
+type Repository[T any] struct {
+	srv     *Server
+	name    string
+	options Options
+	before  func(ctx context.Context, q *RestQuery, model *T) error
+	after   func(ctx context.Context, q *RestQuery, model *T) error
+}
+
+// Resource binds a resource name to Go type T. RegisterResource returns it so
+// callers can inspect the binding, but most code only needs the embedded
+// Repository[T].
+type Resource[T any] struct {
+	Name string
+	*Repository[T]
+}
+
+// RegisterResource binds T to resource name and returns the typed Resource
+// used to read and write it through srv; srv itself keeps no record of the
+// binding. The HTTP layer keeps dispatching on the
+// untyped RestQuery.Action; RegisterResource only adds a compile-time-checked
+// wrapper in front of that dispatch.
+func RegisterResource[T any](srv *Server, name string, options Options) *Resource[T] {
+	if options.PrimaryKey == "" {
+		options.PrimaryKey = "ID"
+	}
+	return &Resource[T]{Name: name, Repository: &Repository[T]{srv: srv, name: name, options: options}}
+}
+
+// BeforeAction installs a hook run before Get, List, Insert, Update, Patch or
+// Delete executes. Returning an error aborts the action.
+func (r *Repository[T]) BeforeAction(fn func(ctx context.Context, q *RestQuery, model *T) error) {
+	r.before = fn
+}
+
+// AfterAction installs a hook run after the action has completed successfully.
+func (r *Repository[T]) AfterAction(fn func(ctx context.Context, q *RestQuery, model *T) error) {
+	r.after = fn
+}
+
+func (r *Repository[T]) runHook(hook func(ctx context.Context, q *RestQuery, model *T) error, ctx context.Context, q *RestQuery, model *T) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(ctx, q, model)
+}
+
+// Get fetches the resource identified by key.
+func (r *Repository[T]) Get(ctx context.Context, key string) (*T, error) {
+	q := &RestQuery{Action: Get, Resource: r.name, Key: key, PrimaryKey: r.options.PrimaryKey}
+	model := new(T)
+	if err := r.runHook(r.before, ctx, q, model); err != nil {
+		return nil, err
+	}
+	if err := r.srv.Execute(ctx, q, model); err != nil {
+		return nil, err
+	}
+	return model, r.runHook(r.after, ctx, q, model)
+}
+
+// List fetches every resource matching q, buffering the whole result set.
+func (r *Repository[T]) List(ctx context.Context, q RestQuery) ([]T, error) {
+	q.Action = All
+	q.Resource = r.name
+	if err := r.runHook(r.before, ctx, &q, nil); err != nil {
+		return nil, err
+	}
+	var models []T
+	if err := r.srv.Execute(ctx, &q, &models); err != nil {
+		return nil, err
+	}
+	return models, r.runHook(r.after, ctx, &q, nil)
+}
+
+// Insert creates model.
+func (r *Repository[T]) Insert(ctx context.Context, model *T) error {
+	q := &RestQuery{Action: Post, Resource: r.name}
+	if err := r.runHook(r.before, ctx, q, model); err != nil {
+		return err
+	}
+	if err := r.srv.Execute(ctx, q, model); err != nil {
+		return err
+	}
+	return r.runHook(r.after, ctx, q, model)
+}
+
+// Update replaces the resource identified by key with model.
+func (r *Repository[T]) Update(ctx context.Context, key string, model *T) error {
+	q := &RestQuery{Action: Put, Resource: r.name, Key: key, PrimaryKey: r.options.PrimaryKey}
+	if err := r.runHook(r.before, ctx, q, model); err != nil {
+		return err
+	}
+	if err := r.srv.Execute(ctx, q, model); err != nil {
+		return err
+	}
+	return r.runHook(r.after, ctx, q, model)
+}
+
+// Patch partially updates the resource identified by key with the non-zero
+// fields of model.
+func (r *Repository[T]) Patch(ctx context.Context, key string, model *T) error {
+	q := &RestQuery{Action: Patch, Resource: r.name, Key: key, PrimaryKey: r.options.PrimaryKey}
+	if err := r.runHook(r.before, ctx, q, model); err != nil {
+		return err
+	}
+	if err := r.srv.Execute(ctx, q, model); err != nil {
+		return err
+	}
+	return r.runHook(r.after, ctx, q, model)
+}
+
+// Delete removes the resource identified by key.
+func (r *Repository[T]) Delete(ctx context.Context, key string) error {
+	q := &RestQuery{Action: Delete, Resource: r.name, Key: key, PrimaryKey: r.options.PrimaryKey}
+	model := new(T)
+	if err := r.runHook(r.before, ctx, q, model); err != nil {
+		return err
+	}
+	if err := r.srv.Execute(ctx, q, model); err != nil {
+		return err
+	}
+	return r.runHook(r.after, ctx, q, model)
+}
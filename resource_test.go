@@ -0,0 +1,110 @@
+package pgrest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aptogeo/pgrest"
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type Todo struct {
+	ID   uuid.UUID `pg:",pk"`
+	Text string
+}
+
+func (t *Todo) BeforeInsert(c context.Context) (context.Context, error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return c, nil
+}
+
+func newTestServer(t *testing.T) *pgrest.Server {
+	db := pg.Connect(&pg.Options{
+		User:               "postgres",
+		Password:           "postgres",
+		IdleCheckFrequency: 100 * time.Millisecond,
+	})
+	err := db.CreateTable((*Todo)(nil), &orm.CreateTableOptions{Temp: true})
+	assert.Nil(t, err)
+	return pgrest.NewServer(db)
+}
+
+func TestRegisterResourceInsertAndGet(t *testing.T) {
+	srv := newTestServer(t)
+	todos := pgrest.RegisterResource[Todo](srv, "todos", pgrest.Options{})
+
+	var beforeCalled, afterCalled bool
+	todos.BeforeAction(func(ctx context.Context, q *pgrest.RestQuery, model *Todo) error {
+		beforeCalled = true
+		return nil
+	})
+	todos.AfterAction(func(ctx context.Context, q *pgrest.RestQuery, model *Todo) error {
+		afterCalled = true
+		return nil
+	})
+
+	todo := &Todo{Text: "write tests"}
+	err := todos.Insert(context.Background(), todo)
+	assert.Nil(t, err)
+	assert.True(t, beforeCalled)
+	assert.True(t, afterCalled)
+
+	fetched, err := todos.Get(context.Background(), todo.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "write tests", fetched.Text)
+}
+
+type Widget struct {
+	Slug string `pg:",pk"`
+	Text string
+}
+
+func TestRegisterResourceCustomPrimaryKey(t *testing.T) {
+	db := pg.Connect(&pg.Options{
+		User:               "postgres",
+		Password:           "postgres",
+		IdleCheckFrequency: 100 * time.Millisecond,
+	})
+	err := db.CreateTable((*Widget)(nil), &orm.CreateTableOptions{Temp: true})
+	assert.Nil(t, err)
+	srv := pgrest.NewServer(db)
+	widgets := pgrest.RegisterResource[Widget](srv, "widgets", pgrest.Options{PrimaryKey: "Slug"})
+
+	assert.Nil(t, widgets.Insert(context.Background(), &Widget{Slug: "widget-a", Text: "hello"}))
+
+	fetched, err := widgets.Get(context.Background(), "widget-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", fetched.Text)
+
+	assert.Nil(t, widgets.Update(context.Background(), "widget-a", &Widget{Slug: "widget-a", Text: "updated"}))
+	fetched, err = widgets.Get(context.Background(), "widget-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "updated", fetched.Text)
+
+	assert.Nil(t, widgets.Delete(context.Background(), "widget-a"))
+	_, err = widgets.Get(context.Background(), "widget-a")
+	assert.NotNil(t, err)
+}
+
+func TestRepositoryList(t *testing.T) {
+	srv := newTestServer(t)
+	todos := pgrest.RegisterResource[Todo](srv, "todos", pgrest.Options{})
+
+	for _, text := range []string{"a", "b", "c"} {
+		assert.Nil(t, todos.Insert(context.Background(), &Todo{Text: text}))
+	}
+
+	all, err := todos.List(context.Background(), pgrest.RestQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(all))
+
+	limited, err := todos.List(context.Background(), pgrest.RestQuery{Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(limited))
+}
@@ -14,13 +14,23 @@ type RestQuery struct {
 	Limit    uint64
 	Fields   []Field
 	Sorts    []Sort
+	Filters  []Filter
+	// PrimaryKey is the Go struct field name of the column that Get, Put,
+	// Patch and Delete filter and update by. Empty means "ID", i.e. the "id"
+	// column. RegisterResource sets this from Options.PrimaryKey; untyped
+	// callers of Server.Execute may set it directly.
+	PrimaryKey string
+	// BatchSize is the number of rows the streaming Iterator returned by
+	// Execute fetches from the database per round trip; zero means 500.
+	// Server.Execute does not use it.
+	BatchSize uint64
 }
 
 func (q *RestQuery) String() string {
 	if q.Key != "" {
 		return fmt.Sprintf("%v: %v[%v] fields=%v", q.Action, q.Resource, q.Key, q.Fields)
 	}
-	return fmt.Sprintf("%v: %v offset=%v limit=%v fields=%v sorts=%v", q.Action, q.Resource, q.Offset, q.Limit, q.Fields, q.Sorts)
+	return fmt.Sprintf("%v: %v offset=%v limit=%v fields=%v sorts=%v filters=%v", q.Action, q.Resource, q.Offset, q.Limit, q.Fields, q.Sorts, FiltersString(q.Filters))
 }
 
 // Field structure
@@ -44,14 +54,3 @@ func (s *Sort) String() string {
 	}
 	return fmt.Sprintf("desc(%v)", s.Name)
 }
-
-// Filter structure
-type Filter struct {
-	Name  string
-	Op    string
-	Value string
-}
-
-func (f *Filter) String() string {
-	return fmt.Sprintf("%v[%v]:%v", f.Name, f.Op, f.Value)
-}
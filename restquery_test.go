@@ -0,0 +1,13 @@
+package pgrest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestQueryStringRendersFilters(t *testing.T) {
+	q := RestQuery{Action: All, Resource: "todos", Filters: []Filter{{Name: "age", Op: Gte, Value: "18"}}}
+	assert.True(t, strings.Contains(q.String(), "filters=[age=gte.18]"), q.String())
+}
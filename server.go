@@ -0,0 +1,148 @@
+package pgrest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aptogeo/pgrest/dialect"
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+)
+
+// Server dispatches a RestQuery against db. It is the untyped entry point the
+// HTTP layer calls for every request; RegisterResource wraps it with a typed
+// Repository so resource-specific code never has to touch interface{}.
+type Server struct {
+	db      *pg.DB
+	dialect dialect.Dialect
+}
+
+// NewServer creates a Server backed by db, targeting PostgreSQL.
+func NewServer(db *pg.DB) *Server {
+	return NewServerWithDialect(db, dialect.Postgres)
+}
+
+// NewServerWithDialect creates a Server backed by db, targeting the given SQL
+// dialect. db is a go-pg connection, which speaks Postgres' wire protocol, so
+// d only makes sense as dialect.Postgres or a wire-compatible backend such as
+// dialect.Cockroach; pgrest has no database/sql adapter, so a true MySQL/TiDB
+// backend cannot be targeted at all. Execute binds d with
+// transactional.ContextWithSavepointDialect for the transactions it runs; its
+// go-pg-backed query building does not consult d. The streaming Iterator
+// uses d only when the caller binds it with ContextWithDialect (for example
+// ContextWithDialect(ctx, srv.Dialect())); the Server does not do so itself.
+func NewServerWithDialect(db *pg.DB, d dialect.Dialect) *Server {
+	return &Server{db: db, dialect: d}
+}
+
+// DB returns the *pg.DB backing the server, mainly so callers can build a
+// context with transactional.ContextWithDb for APIs such as Execute that need
+// one directly.
+func (s *Server) DB() *pg.DB {
+	return s.db
+}
+
+// Dialect returns the SQL dialect the server was configured with.
+func (s *Server) Dialect() dialect.Dialect {
+	return s.dialect
+}
+
+// primaryKeyColumn resolves q.PrimaryKey, a Go struct field name as set by
+// Options.PrimaryKey, to its SQL column on table. An empty q.PrimaryKey
+// resolves to "id", matching the zero-value Options used by untyped callers.
+func primaryKeyColumn(table *orm.Table, primaryKey string) (string, error) {
+	if primaryKey == "" {
+		return "id", nil
+	}
+	for _, field := range table.Fields {
+		if field.GoName == primaryKey {
+			return field.SQLName, nil
+		}
+	}
+	return "", fmt.Errorf("pgrest: unknown primary key field %q on %v", primaryKey, table)
+}
+
+// executeInTx runs fn against a transaction, joining one already active in
+// ctx or beginning and committing a new top-level one otherwise. Joining lets
+// a caller such as audit.Middleware thread its own transaction through the
+// request context so a mutation and the audit row describing it commit or
+// roll back together.
+func (s *Server) executeInTx(ctx context.Context, fn func(ctx context.Context, tx *pg.Tx) error) error {
+	ctx = transactional.ContextWithDb(ctx, s.db)
+	ctx = transactional.ContextWithSavepointDialect(ctx, s.dialect)
+	return transactional.Execute(ctx, fn)
+}
+
+// Execute runs q against model, dispatching on q.Action.
+func (s *Server) Execute(ctx context.Context, q *RestQuery, model interface{}) error {
+	switch q.Action {
+	case Get:
+		query := s.db.ModelContext(ctx, model)
+		column, err := primaryKeyColumn(query.TableModel().Table(), q.PrimaryKey)
+		if err != nil {
+			return err
+		}
+		query = query.Where("? = ?", pg.Ident(column), q.Key)
+		if err := ApplyFilters(query, q.Filters); err != nil {
+			return err
+		}
+		return query.Select()
+	case All:
+		query := s.db.ModelContext(ctx, model)
+		if err := ApplyFilters(query, q.Filters); err != nil {
+			return err
+		}
+		for _, sort := range q.Sorts {
+			if sort.Asc {
+				query = query.OrderExpr("? ASC", pg.Ident(sort.Name))
+			} else {
+				query = query.OrderExpr("? DESC", pg.Ident(sort.Name))
+			}
+		}
+		if q.Limit > 0 {
+			query = query.Limit(int(q.Limit))
+		}
+		if q.Offset > 0 {
+			query = query.Offset(int(q.Offset))
+		}
+		return query.Select()
+	case Post:
+		return s.executeInTx(ctx, func(ctx context.Context, tx *pg.Tx) error {
+			_, err := tx.ModelContext(ctx, model).Insert()
+			return err
+		})
+	case Put:
+		return s.executeInTx(ctx, func(ctx context.Context, tx *pg.Tx) error {
+			query := tx.ModelContext(ctx, model)
+			column, err := primaryKeyColumn(query.TableModel().Table(), q.PrimaryKey)
+			if err != nil {
+				return err
+			}
+			_, err = query.Where("? = ?", pg.Ident(column), q.Key).Update()
+			return err
+		})
+	case Patch:
+		return s.executeInTx(ctx, func(ctx context.Context, tx *pg.Tx) error {
+			query := tx.ModelContext(ctx, model)
+			column, err := primaryKeyColumn(query.TableModel().Table(), q.PrimaryKey)
+			if err != nil {
+				return err
+			}
+			_, err = query.Where("? = ?", pg.Ident(column), q.Key).UpdateNotZero()
+			return err
+		})
+	case Delete:
+		return s.executeInTx(ctx, func(ctx context.Context, tx *pg.Tx) error {
+			query := tx.ModelContext(ctx, model)
+			column, err := primaryKeyColumn(query.TableModel().Table(), q.PrimaryKey)
+			if err != nil {
+				return err
+			}
+			_, err = query.Where("? = ?", pg.Ident(column), q.Key).Delete()
+			return err
+		})
+	default:
+		return fmt.Errorf("pgrest: unsupported action %v for Execute", q.Action)
+	}
+}
@@ -0,0 +1,284 @@
+// Package transactional provides Spring-style transaction propagation on top
+// of go-pg, threading the active *pg.Tx through context.Context.
+package transactional
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+)
+
+type dbKey struct{}
+type txKey struct{}
+type savepointDialectKey struct{}
+
+// SavepointDialect renders the SAVEPOINT statement Savepoint propagation
+// issues when nesting inside an already-active transaction, or reports that
+// the backend does not support it at all. dialect.Dialect implements this.
+type SavepointDialect interface {
+	// SavepointSyntax renders the SAVEPOINT statement for name, or returns ""
+	// if the backend does not support savepoints.
+	SavepointSyntax(name string) string
+}
+
+// ContextWithSavepointDialect returns a context carrying d, consulted by
+// Savepoint propagation to render backend-correct SQL. Without one bound,
+// Savepoint propagation assumes plain "SAVEPOINT name" syntax.
+func ContextWithSavepointDialect(ctx context.Context, d SavepointDialect) context.Context {
+	return context.WithValue(ctx, savepointDialectKey{}, d)
+}
+
+func savepointDialectFromContext(ctx context.Context) SavepointDialect {
+	d, _ := ctx.Value(savepointDialectKey{}).(SavepointDialect)
+	return d
+}
+
+// Propagation controls how Execute joins or creates a transaction.
+type Propagation int
+
+const (
+	// Current joins the transaction in ctx if there is one, otherwise it
+	// begins a new top-level transaction.
+	Current Propagation = iota
+	// Mandatory requires a transaction to already be active in ctx and fails
+	// otherwise.
+	Mandatory
+	// Savepoint runs fn inside a savepoint nested in the current transaction
+	// if there is one (or directly in it, when the bound SavepointDialect
+	// reports no savepoint support); otherwise it begins a new top-level
+	// transaction without a savepoint.
+	Savepoint
+	// RequiresNew suspends any transaction in ctx and begins a fresh
+	// top-level transaction that commits or rolls back independently of it,
+	// then resumes the suspended transaction on return.
+	RequiresNew
+	// NotSupported suspends any transaction in ctx and runs fn outside a
+	// transaction, then resumes the suspended transaction on return.
+	NotSupported
+	// Never fails if a transaction is already active in ctx, otherwise it
+	// runs fn outside a transaction.
+	Never
+	// Supports joins the transaction in ctx if there is one, otherwise it
+	// runs fn outside a transaction.
+	Supports
+)
+
+// TxOptions configures ExecuteWithOptions.
+type TxOptions struct {
+	Propagation Propagation
+	// Isolation and ReadOnly are only applied when a new top-level
+	// transaction is begun; they have no effect when joining an existing one.
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// Timeout, when non-zero, cancels the context passed to fn once elapsed.
+	Timeout time.Duration
+}
+
+// ContextWithDb returns a context carrying db, used by Execute to begin the
+// first transaction when none is active yet.
+func ContextWithDb(ctx context.Context, db *pg.DB) context.Context {
+	return context.WithValue(ctx, dbKey{}, db)
+}
+
+func dbFromContext(ctx context.Context) (*pg.DB, error) {
+	db, ok := ctx.Value(dbKey{}).(*pg.DB)
+	if !ok {
+		return nil, errors.New("transactional: no *pg.DB in context, call ContextWithDb first")
+	}
+	return db, nil
+}
+
+func txFromContext(ctx context.Context) (*pg.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*pg.Tx)
+	if !ok || tx == nil {
+		return nil, false
+	}
+	return tx, true
+}
+
+func contextWithTx(ctx context.Context, tx *pg.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// suspend returns a context in which no transaction is current. The original
+// ctx is left untouched, so the caller resumes it simply by continuing to use
+// it once the suspended callback returns.
+func suspend(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txKey{}, (*pg.Tx)(nil))
+}
+
+// Begin starts a new top-level transaction over the *pg.DB stored in ctx and
+// returns it along with a context that has it bound. It is meant for callers
+// whose lifetime cannot be expressed as a single Execute callback, such as a
+// streaming result iterator; most code should prefer Execute or
+// ExecuteWithPropagation instead.
+func Begin(ctx context.Context) (*pg.Tx, context.Context, error) {
+	db, err := dbFromContext(ctx)
+	if err != nil {
+		return nil, ctx, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, ctx, err
+	}
+	return tx, contextWithTx(ctx, tx), nil
+}
+
+// Execute runs fn with Current propagation.
+func Execute(ctx context.Context, fn func(ctx context.Context, tx *pg.Tx) error) error {
+	return executeWithPropagation(ctx, Current, nil, fn)
+}
+
+// ExecuteWithPropagation runs fn according to propagation.
+func ExecuteWithPropagation(ctx context.Context, propagation Propagation, fn func(ctx context.Context, tx *pg.Tx) error) error {
+	return executeWithPropagation(ctx, propagation, nil, fn)
+}
+
+// ExecuteWithOptions runs fn according to opts.Propagation, additionally
+// setting the isolation level and read-only mode of any transaction it
+// begins and enforcing a timeout on ctx. When propagation joins an existing
+// transaction, Isolation and ReadOnly are ignored since they can only be set
+// right after BEGIN.
+//
+// Under NotSupported, Never (when no transaction is active) and Supports
+// (when no transaction is active), fn is called with a nil *pg.Tx; use the
+// *pg.DB obtained via ContextWithDb for unmanaged access in that case.
+func ExecuteWithOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx *pg.Tx) error) error {
+	return executeWithPropagation(ctx, opts.Propagation, &opts, fn)
+}
+
+func executeWithPropagation(ctx context.Context, propagation Propagation, opts *TxOptions, fn func(ctx context.Context, tx *pg.Tx) error) error {
+	if opts != nil && opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	tx, hasCurrent := txFromContext(ctx)
+	switch propagation {
+	case Mandatory:
+		if !hasCurrent {
+			return errors.New("transactional: Mandatory propagation requires an active transaction")
+		}
+		return fn(ctx, tx)
+	case Never:
+		if hasCurrent {
+			return errors.New("transactional: Never propagation forbids an active transaction")
+		}
+		return fn(ctx, nil)
+	case Supports:
+		if hasCurrent {
+			return fn(ctx, tx)
+		}
+		return fn(suspend(ctx), nil)
+	case NotSupported:
+		return fn(suspend(ctx), nil)
+	case RequiresNew:
+		return beginAndRun(suspend(ctx), opts, fn)
+	case Savepoint:
+		if hasCurrent {
+			return runInSavepoint(ctx, tx, fn)
+		}
+		return beginAndRun(ctx, opts, fn)
+	default: // Current
+		if hasCurrent {
+			return fn(ctx, tx)
+		}
+		return beginAndRun(ctx, opts, fn)
+	}
+}
+
+func beginAndRun(ctx context.Context, opts *TxOptions, fn func(ctx context.Context, tx *pg.Tx) error) error {
+	tx, txCtx, err := Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if opts != nil {
+		if err := applyTxOptions(tx, *opts); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return runAndFinish(txCtx, tx, fn)
+}
+
+func applyTxOptions(tx *pg.Tx, opts TxOptions) error {
+	if opts.Isolation != 0 {
+		level, err := isolationSQL(opts.Isolation)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("SET TRANSACTION ISOLATION LEVEL " + level); err != nil {
+			return err
+		}
+	}
+	if opts.ReadOnly {
+		if _, err := tx.Exec("SET TRANSACTION READ ONLY"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isolationSQL(level sql.IsolationLevel) (string, error) {
+	switch level {
+	case sql.LevelReadUncommitted:
+		return "READ UNCOMMITTED", nil
+	case sql.LevelReadCommitted:
+		return "READ COMMITTED", nil
+	case sql.LevelRepeatableRead:
+		return "REPEATABLE READ", nil
+	case sql.LevelSerializable:
+		return "SERIALIZABLE", nil
+	default:
+		return "", fmt.Errorf("transactional: unsupported isolation level %v", level)
+	}
+}
+
+func runAndFinish(ctx context.Context, tx *pg.Tx, fn func(ctx context.Context, tx *pg.Tx) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err = fn(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+var savepointSeq int64
+
+func runInSavepoint(ctx context.Context, tx *pg.Tx, fn func(ctx context.Context, tx *pg.Tx) error) (err error) {
+	name := fmt.Sprintf("pgrest_sp_%d", atomic.AddInt64(&savepointSeq, 1))
+	stmt := "SAVEPOINT " + name
+	if d := savepointDialectFromContext(ctx); d != nil {
+		if stmt = d.SavepointSyntax(name); stmt == "" {
+			// The dialect reports no savepoint support for this backend (an
+			// older CockroachDB, say): there is nothing weaker to nest in, so
+			// run fn directly in tx instead of failing the whole operation.
+			return fn(ctx, tx)
+		}
+	}
+	if _, err = tx.Exec(stmt); err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Exec("ROLLBACK TO SAVEPOINT ?", pg.Ident(name))
+			panic(p)
+		}
+	}()
+	if err = fn(ctx, tx); err != nil {
+		tx.Exec("ROLLBACK TO SAVEPOINT ?", pg.Ident(name))
+		return err
+	}
+	_, err = tx.Exec("RELEASE SAVEPOINT ?", pg.Ident(name))
+	return err
+}
@@ -0,0 +1,98 @@
+package transactional_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/aptogeo/pgrest/transactional"
+	"github.com/go-pg/pg/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionalNeverForbidsActiveTx(t *testing.T) {
+	db := initTests(t)
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	err := transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+		return transactional.ExecuteWithPropagation(ctx, transactional.Never, func(ctx context.Context, tx *pg.Tx) error {
+			return nil
+		})
+	})
+	assert.NotNil(t, err)
+}
+
+func TestTransactionalNeverRunsWithoutActiveTx(t *testing.T) {
+	db := initTests(t)
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	err := transactional.ExecuteWithPropagation(ctx, transactional.Never, func(ctx context.Context, tx *pg.Tx) error {
+		assert.Nil(t, tx)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func TestTransactionalRequiresNewIndependentFromOuter(t *testing.T) {
+	db := initTests(t)
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	err := transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+		todo := &Todo{Text: "outer"}
+		if err := tx.Insert(todo); err != nil {
+			return err
+		}
+		innerErr := transactional.ExecuteWithPropagation(ctx, transactional.RequiresNew, func(ctx context.Context, tx *pg.Tx) error {
+			todo := &Todo{Text: "inner"}
+			tx.Insert(todo)
+			return errors.New("inner ko")
+		})
+		assert.NotNil(t, innerErr)
+		return nil
+	})
+	assert.Nil(t, err)
+	count, err := db.Model(&Todo{}).Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestTransactionalNotSupportedRunsWithoutTx(t *testing.T) {
+	db := initTests(t)
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	err := transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+		return transactional.ExecuteWithPropagation(ctx, transactional.NotSupported, func(ctx context.Context, tx *pg.Tx) error {
+			assert.Nil(t, tx)
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+}
+
+func TestTransactionalSupports(t *testing.T) {
+	db := initTests(t)
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	err := transactional.ExecuteWithPropagation(ctx, transactional.Supports, func(ctx context.Context, tx *pg.Tx) error {
+		assert.Nil(t, tx)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+		return transactional.ExecuteWithPropagation(ctx, transactional.Supports, func(ctx context.Context, tx *pg.Tx) error {
+			assert.NotNil(t, tx)
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+}
+
+func TestTransactionalExecuteWithOptionsIsolationAndReadOnly(t *testing.T) {
+	db := initTests(t)
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	err := transactional.ExecuteWithOptions(ctx, transactional.TxOptions{
+		Propagation: transactional.Current,
+		Isolation:   sql.LevelSerializable,
+		ReadOnly:    true,
+	}, func(ctx context.Context, tx *pg.Tx) error {
+		return nil
+	})
+	assert.Nil(t, err)
+}
@@ -210,6 +210,32 @@ func TestTransactionalSavepointOKSavepointKO(t *testing.T) {
 	assert.Equal(t, 1, count)
 }
 
+type noSavepointDialect struct{}
+
+func (noSavepointDialect) SavepointSyntax(name string) string { return "" }
+
+func TestTransactionalSavepointDialectWithoutSupportSkipsNesting(t *testing.T) {
+	db := initTests(t)
+	var err error
+	ctx := transactional.ContextWithDb(context.Background(), db)
+	ctx = transactional.ContextWithSavepointDialect(ctx, noSavepointDialect{})
+	err = transactional.Execute(ctx, func(ctx context.Context, tx *pg.Tx) error {
+		todo := &Todo{Text: "outer"}
+		if err := tx.Insert(todo); err != nil {
+			return err
+		}
+		return transactional.ExecuteWithPropagation(ctx, transactional.Savepoint, func(ctx context.Context, tx *pg.Tx) error {
+			todo := &Todo{Text: "inner"}
+			tx.Insert(todo)
+			return errors.New("inner ko")
+		})
+	})
+	assert.NotNil(t, err)
+	count, err := db.Model(&Todo{}).Count()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
+
 func TestTransactionalCurrentOKSavepointKO(t *testing.T) {
 	db := initTests(t)
 	var err error